@@ -1,15 +1,31 @@
-// +build todo
-
 package ini
 
 import (
+	"encoding"
 	"errors"
 	"io"
 	"reflect"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// DecodeOptions controls the behavior of a Decoder beyond the basic
+// type conversions.
+type DecodeOptions struct {
+	// DisallowUnknownFields makes it an error for the input to contain
+	// a section or key that doesn't correspond to a field in the
+	// target struct, instead of silently ignoring it. It has no effect
+	// on map targets, or on a struct with an `ini:",inline"` field.
+	DisallowUnknownFields bool
+}
+
 type Decoder struct {
 	p *Parser
+
+	// Options controls decoding behavior for struct targets. It is
+	// zero-valued, and thus fully permissive, by default.
+	Options DecodeOptions
 }
 
 func NewDecoder(r io.Reader) *Decoder {
@@ -38,13 +54,13 @@ func (d *Decoder) Decode(v interface{}) error {
 		switch et := t.Elem(); et.Kind() {
 		case reflect.Map:
 			dec = &decoderMap{v: val.Elem(), t: t}
-		//case reflect.Struct:
-		//	dec = &decoderStruct{val.Elem(), t}
+		case reflect.Struct:
+			dec = &decoderStruct{v: val.Elem(), t: et, opts: d.Options}
 		default:
-			return &DecodeTypeError{t}
+			return &DecodeTypeError{Type: t}
 		}
 	default:
-		return &DecodeTypeError{t}
+		return &DecodeTypeError{Type: t}
 	}
 
 	err := dec.ok()
@@ -70,7 +86,9 @@ func (d *Decoder) Decode(v interface{}) error {
 				return errors.New("Section found but doesn't exist in v: " + section)
 			}
 		case *SettingToken:
-			dec.set(section, t.Left, t.Right)
+			if err := dec.set(section, t.Left, t.Right); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -80,7 +98,7 @@ func (d *Decoder) Decode(v interface{}) error {
 type decoderType interface {
 	ok() error
 	exists(section string) bool
-	set(section, key, val string)
+	set(section, key, val string) error
 }
 
 type decoderMap struct {
@@ -92,25 +110,25 @@ type decoderMap struct {
 
 func (d *decoderMap) ok() error {
 	if d.v.Type().Key().Kind() != reflect.String {
-		return &DecodeTypeError{d.t}
+		return &DecodeTypeError{Type: d.t}
 	}
 
 	switch et := d.v.Type().Elem(); et.Kind() {
 	case reflect.String:
 	case reflect.Map:
 		if et.Key().Kind() != reflect.String {
-			return &DecodeTypeError{d.t}
+			return &DecodeTypeError{Type: d.t}
 		}
 
 		switch et.Elem().Kind() {
 		case reflect.String:
 		default:
-			return &DecodeTypeError{d.t}
+			return &DecodeTypeError{Type: d.t}
 		}
 
 		d.submap = true
 	default:
-		return &DecodeTypeError{d.t}
+		return &DecodeTypeError{Type: d.t}
 	}
 
 	if d.v.IsNil() {
@@ -128,7 +146,7 @@ func (d *decoderMap) exists(section string) bool {
 	return true
 }
 
-func (d *decoderMap) set(section, key, val string) {
+func (d *decoderMap) set(section, key, val string) error {
 	if !d.submap {
 		key = section + "/" + key
 		if section == "" {
@@ -137,7 +155,7 @@ func (d *decoderMap) set(section, key, val string) {
 
 		d.v.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(val))
 
-		return
+		return nil
 	}
 
 	m := d.v.MapIndex(reflect.ValueOf(section))
@@ -149,13 +167,279 @@ func (d *decoderMap) set(section, key, val string) {
 	}
 
 	sub := &decoderMap{v: m}
-	sub.set("", key, val)
+	return sub.set("", key, val)
+}
+
+// decoderStruct decodes into a struct, where each exported field
+// tagged `ini:"name"` (or, lacking a tag, named after the field)
+// whose type is a struct or pointer to struct represents a section.
+type decoderStruct struct {
+	v    reflect.Value
+	t    reflect.Type
+	opts DecodeOptions
+
+	sections map[string]*structSection
 }
 
+// structSection is a single section's worth of target fields: the
+// fields reachable directly on the section struct, plus, if present,
+// an `ini:",inline"` field that catches anything else.
+type structSection struct {
+	path   string
+	fields map[string]reflect.Value
+	inline decoderType
+	opts   DecodeOptions
+}
+
+func (d *decoderStruct) ok() error {
+	if d.t.Kind() != reflect.Struct {
+		return &DecodeTypeError{Type: d.t}
+	}
+
+	d.sections = make(map[string]*structSection)
+
+	for i := 0; i < d.t.NumField(); i++ {
+		f := d.t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name, _ := parseTag(f)
+
+		fv := d.v.Field(i)
+		for fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			fv = fv.Elem()
+		}
+
+		if fv.Kind() != reflect.Struct {
+			return &DecodeTypeError{Type: d.t, Field: name}
+		}
+
+		section, err := newStructSection(name, fv, d.opts)
+		if err != nil {
+			return err
+		}
+
+		d.sections[name] = section
+	}
+
+	return nil
+}
+
+func newStructSection(path string, v reflect.Value, opts DecodeOptions) (*structSection, error) {
+	s := &structSection{
+		path:   path,
+		fields: make(map[string]reflect.Value),
+		opts:   opts,
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		name, inline := parseInlineTag(f)
+		fv := v.Field(i)
+
+		if inline {
+			switch fv.Kind() {
+			case reflect.Map:
+				d := &decoderMap{v: fv, t: fv.Type()}
+				if err := d.ok(); err != nil {
+					return nil, err
+				}
+
+				s.inline = d
+			case reflect.Struct:
+				sub, err := newStructSection(path, fv, opts)
+				if err != nil {
+					return nil, err
+				}
+
+				s.inline = sub
+			default:
+				return nil, &DecodeTypeError{Type: fv.Type(), Field: path}
+			}
+
+			continue
+		}
+
+		s.fields[name] = fv
+	}
+
+	return s, nil
+}
+
+func (s *structSection) ok() error { return nil }
+
+func (s *structSection) exists(section string) bool { return true }
+
+func (s *structSection) set(section, key, val string) error {
+	fv, ok := s.fields[key]
+	if !ok {
+		if s.inline != nil {
+			// An inline map or struct takes over the rest of this
+			// section, so it gets no section prefix of its own.
+			return s.inline.set("", key, val)
+		}
+
+		if s.opts.DisallowUnknownFields {
+			path := key
+			if s.path != "" {
+				path = s.path + "/" + key
+			}
+
+			return errors.New("unknown key: " + path)
+		}
+
+		return nil
+	}
+
+	path := key
+	if s.path != "" {
+		path = s.path + "/" + key
+	}
+
+	return setLeaf(fv, val, path)
+}
+
+func (d *decoderStruct) exists(section string) bool {
+	if _, ok := d.sections[section]; ok {
+		return true
+	}
+
+	return !d.opts.DisallowUnknownFields
+}
+
+func (d *decoderStruct) set(section, key, val string) error {
+	s, ok := d.sections[section]
+	if !ok {
+		if d.opts.DisallowUnknownFields {
+			return errors.New("unknown section: " + section)
+		}
+
+		return nil
+	}
+
+	return s.set(section, key, val)
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setLeaf assigns val, converted to fv's type, to fv. path is used
+// only to annotate any resulting DecodeTypeError.
+func setLeaf(fv reflect.Value, val, path string) error {
+	if fv.Kind() == reflect.Slice && fv.Type() != durationType {
+		elem := reflect.New(fv.Type().Elem()).Elem()
+		if err := setScalar(elem, val, path); err != nil {
+			return err
+		}
+
+		fv.Set(reflect.Append(fv, elem))
+		return nil
+	}
+
+	return setScalar(fv, val, path)
+}
+
+func setScalar(fv reflect.Value, val, path string) error {
+	if fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalerType) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+	}
+
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(val, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		fv.SetFloat(n)
+	default:
+		return &DecodeTypeError{Type: fv.Type(), Field: path}
+	}
+
+	return nil
+}
+
+// parseInlineTag is like parseTag, but also reports whether the field
+// is tagged `ini:",inline"`.
+func parseInlineTag(f reflect.StructField) (name string, inline bool) {
+	name = f.Name
+
+	tag := f.Tag.Get("ini")
+	if tag == "" {
+		return name, false
+	}
+
+	parts := strings.Split(tag, ",")
+	if parts[0] != "" {
+		name = parts[0]
+	}
+
+	for _, opt := range parts[1:] {
+		if opt == "inline" {
+			inline = true
+		}
+	}
+
+	return name, inline
+}
+
+// DecodeTypeError is returned by (*Decoder).Decode when v, or one of
+// its fields, isn't a type that can be decoded into.
 type DecodeTypeError struct {
 	Type reflect.Type
+
+	// Field is the dotted path, if any, of the field that caused the
+	// error.
+	Field string
 }
 
 func (err *DecodeTypeError) Error() string {
-	return "Can't decode into type: " + err.Type.String()
+	msg := "Can't decode into type: " + err.Type.String()
+	if err.Field != "" {
+		msg += " (field " + err.Field + ")"
+	}
+
+	return msg
 }
@@ -1,11 +1,11 @@
-// +build todo
-
 package ini_test
 
 import (
 	"fmt"
 	"github.com/DeedleFake/ini"
 	"strings"
+	"testing"
+	"time"
 )
 
 func ExampleNewDecoder() {
@@ -21,8 +21,146 @@ an=example`
 		panic(err)
 	}
 
-	fmt.Printf("this: %v\n", m["Section/this"])
-	fmt.Printf("an: %v\n", m["Section/an"])
+	fmt.Printf("this: %v\n", m["Section"]["this"])
+	fmt.Printf("an: %v\n", m["Section"]["an"])
 	// Output: this: is
 	// an: example
 }
+
+func ExampleDecoder_Decode_struct() {
+	const example = `[server]
+host=localhost
+port=8080
+timeout=5s`
+
+	type Server struct {
+		Host    string        `ini:"host"`
+		Port    int           `ini:"port"`
+		Timeout time.Duration `ini:"timeout"`
+	}
+
+	var cfg struct {
+		Server Server `ini:"server"`
+	}
+
+	d := ini.NewDecoder(strings.NewReader(example))
+	if err := d.Decode(&cfg); err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%v:%v (timeout %v)\n", cfg.Server.Host, cfg.Server.Port, cfg.Server.Timeout)
+	// Output: localhost:8080 (timeout 5s)
+}
+
+func ExampleDecoder_Decode_slice() {
+	const example = `[server]
+tag=a
+tag=b
+tag=c`
+
+	var cfg struct {
+		Server struct {
+			Tags []string `ini:"tag"`
+		} `ini:"server"`
+	}
+
+	d := ini.NewDecoder(strings.NewReader(example))
+	if err := d.Decode(&cfg); err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cfg.Server.Tags)
+	// Output: [a b c]
+}
+
+func TestDecoderInline(t *testing.T) {
+	const example = `[server]
+host=localhost
+extra1=foo
+extra2=bar`
+
+	var cfg struct {
+		Server struct {
+			Host  string            `ini:"host"`
+			Extra map[string]string `ini:",inline"`
+		} `ini:"server"`
+	}
+
+	d := ini.NewDecoder(strings.NewReader(example))
+	if err := d.Decode(&cfg); err != nil {
+		t.Fatalf("d.Decode(): %v", err)
+	}
+
+	if cfg.Server.Host != "localhost" {
+		t.Fatalf("got Host %q, want %q", cfg.Server.Host, "localhost")
+	}
+	if (cfg.Server.Extra["extra1"] != "foo") || (cfg.Server.Extra["extra2"] != "bar") {
+		t.Fatalf("got Extra %v, want map[extra1:foo extra2:bar]", cfg.Server.Extra)
+	}
+}
+
+func TestDecoderDisallowUnknownFields(t *testing.T) {
+	type Server struct {
+		Host string `ini:"host"`
+	}
+
+	t.Run("UnknownKey", func(t *testing.T) {
+		const example = `[server]
+host=localhost
+bogus=1`
+
+		var cfg struct {
+			Server Server `ini:"server"`
+		}
+
+		d := ini.NewDecoder(strings.NewReader(example))
+		d.Options.DisallowUnknownFields = true
+		if err := d.Decode(&cfg); err == nil {
+			t.Fatalf("d.Decode(): got nil error, want one for the unknown key")
+		}
+	})
+
+	t.Run("UnknownSection", func(t *testing.T) {
+		const example = `[server]
+host=localhost
+
+[bogus]
+key=1`
+
+		var cfg struct {
+			Server Server `ini:"server"`
+		}
+
+		d := ini.NewDecoder(strings.NewReader(example))
+		d.Options.DisallowUnknownFields = true
+		if err := d.Decode(&cfg); err == nil {
+			t.Fatalf("d.Decode(): got nil error, want one for the unknown section")
+		}
+	})
+}
+
+type upperString string
+
+func (s *upperString) UnmarshalText(text []byte) error {
+	*s = upperString(strings.ToUpper(string(text)))
+	return nil
+}
+
+func ExampleDecoder_Decode_textUnmarshaler() {
+	const example = `[server]
+host=localhost`
+
+	var cfg struct {
+		Server struct {
+			Host upperString `ini:"host"`
+		} `ini:"server"`
+	}
+
+	d := ini.NewDecoder(strings.NewReader(example))
+	if err := d.Decode(&cfg); err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cfg.Server.Host)
+	// Output: LOCALHOST
+}
@@ -0,0 +1,410 @@
+package ini
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// A Pair is an explicitly ordered setting, used in place of a map when
+// the order that settings are written in matters.
+type Pair struct {
+	Key   string
+	Value string
+}
+
+// A Group pairs a section name with an ordered list of settings. It
+// can be passed to (*Encoder).Encode, either directly or as part of a
+// []Group, to control output order without relying on map iteration
+// order.
+type Group struct {
+	// Name is the name of the section. An empty Name writes Settings
+	// without a section header.
+	Name string
+
+	Settings []Pair
+}
+
+// An Encoder writes INI-formatted output. It uses the same runes as a
+// Parser to recognize comments, escapes, and section and setting
+// delimiters, so that output from an Encoder can be read back by a
+// correspondingly configured Parser.
+type Encoder struct {
+	w *bufio.Writer
+
+	// Comments contains all runes which start a comment. A value or
+	// section name containing one of these runes will have it escaped.
+	//
+	// Default: #;
+	Comments string
+
+	// Escaper is the rune that marks the start of an escape sequence.
+	//
+	// Default: \
+	Escaper rune
+
+	// Escapes maps escape sequences to what they should be replaced
+	// with. It is used in reverse to pick an escape sequence for a
+	// rune that needs escaping.
+	//
+	// Default: the same as Parser's.
+	Escapes map[rune]string
+
+	// SectionStart is the rune which starts a section token.
+	//
+	// Default: [
+	SectionStart rune
+
+	// SectionEnd is the rune which ends a section token.
+	//
+	// Default: ]
+	SectionEnd rune
+
+	// Equals is the rune that separates the left side of a setting
+	// token from the right.
+	//
+	// Default: =
+	Equals rune
+
+	// SortKeys controls whether the keys of map values are sorted
+	// before being written. It has no effect on []Pair or []Group
+	// values, which are always written in the order given.
+	//
+	// Default: true
+	SortKeys bool
+}
+
+// NewEncoder initializes a new Encoder that writes to w, using the
+// same defaults as NewParser.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w: bufio.NewWriter(w),
+
+		Comments: "#;",
+
+		Escaper: '\\',
+		Escapes: map[rune]string{
+			'0':  "\000",
+			'a':  "\a",
+			'b':  "\b",
+			't':  "\t",
+			'r':  "\r",
+			'n':  "\n",
+			'\n': "",
+		},
+
+		SectionStart: '[',
+		SectionEnd:   ']',
+
+		Equals: '=',
+
+		SortKeys: true,
+	}
+}
+
+// Marshal encodes v into INI-formatted output. See (*Encoder).Encode
+// for what v may be.
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Encode writes v to the Encoder's underlying io.Writer.
+//
+// v may be a *File or *Section (using its Sections/Keys in order, the
+// same document model (*File).WriteTo builds on), a map[string]string
+// (flat, with "Section/key" split on "/"), a map[string]map[string]string
+// (nested), a []Pair or []Group (explicitly ordered), or a struct with
+// fields tagged `ini:"name,omitempty"` where each top-level field is a
+// section and nested struct or map fields are that section's settings.
+func (e *Encoder) Encode(v interface{}) error {
+	switch v := v.(type) {
+	case *File:
+		return e.encodeFile(v)
+	case *Section:
+		return e.encodeSection(v)
+	}
+
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Map:
+		return e.encodeMap(val)
+	case reflect.Slice:
+		return e.encodeSlice(val)
+	case reflect.Struct:
+		return e.encodeStruct(val)
+	default:
+		return &EncodeTypeError{val.Type()}
+	}
+}
+
+func (e *Encoder) encodeFile(f *File) error {
+	for _, s := range f.Sections() {
+		if err := e.writeSection(sectionGroup(s)); err != nil {
+			return err
+		}
+	}
+
+	return e.w.Flush()
+}
+
+func (e *Encoder) encodeSection(s *Section) error {
+	if err := e.writeSection(sectionGroup(s)); err != nil {
+		return err
+	}
+
+	return e.w.Flush()
+}
+
+func sectionGroup(s *Section) Group {
+	g := Group{Name: s.Name}
+	for _, k := range s.Keys() {
+		g.Settings = append(g.Settings, Pair{Key: k.Name, Value: k.Value})
+	}
+
+	return g
+}
+
+func (e *Encoder) encodeMap(val reflect.Value) error {
+	t := val.Type()
+	if t.Key().Kind() != reflect.String {
+		return &EncodeTypeError{t}
+	}
+
+	switch t.Elem().Kind() {
+	case reflect.String:
+		sections := make(map[string][]Pair)
+		for _, k := range val.MapKeys() {
+			section, key := splitSectionKey(k.String())
+			sections[section] = append(sections[section], Pair{
+				Key:   key,
+				Value: val.MapIndex(k).String(),
+			})
+		}
+
+		return e.writeSections(sections)
+	case reflect.Map:
+		sections := make(map[string][]Pair)
+		for _, k := range val.MapKeys() {
+			sub := val.MapIndex(k)
+			for _, sk := range sub.MapKeys() {
+				sections[k.String()] = append(sections[k.String()], Pair{
+					Key:   sk.String(),
+					Value: sub.MapIndex(sk).String(),
+				})
+			}
+		}
+
+		return e.writeSections(sections)
+	default:
+		return &EncodeTypeError{t}
+	}
+}
+
+func (e *Encoder) writeSections(sections map[string][]Pair) error {
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	if e.SortKeys {
+		sort.Strings(names)
+	}
+
+	for _, name := range names {
+		settings := sections[name]
+		if e.SortKeys {
+			sort.Slice(settings, func(i, j int) bool {
+				return settings[i].Key < settings[j].Key
+			})
+		}
+
+		if err := e.writeSection(Group{Name: name, Settings: settings}); err != nil {
+			return err
+		}
+	}
+
+	return e.w.Flush()
+}
+
+func (e *Encoder) encodeSlice(val reflect.Value) error {
+	switch val.Type().Elem() {
+	case reflect.TypeOf(Pair{}):
+		settings := make([]Pair, val.Len())
+		for i := range settings {
+			settings[i] = val.Index(i).Interface().(Pair)
+		}
+
+		if err := e.writeSection(Group{Settings: settings}); err != nil {
+			return err
+		}
+
+		return e.w.Flush()
+	case reflect.TypeOf(Group{}):
+		for i := 0; i < val.Len(); i++ {
+			if err := e.writeSection(val.Index(i).Interface().(Group)); err != nil {
+				return err
+			}
+		}
+
+		return e.w.Flush()
+	default:
+		return &EncodeTypeError{val.Type()}
+	}
+}
+
+func (e *Encoder) writeSection(s Group) error {
+	if s.Name != "" {
+		if _, err := fmt.Fprintf(e.w, "%c%v%c\n", e.SectionStart, e.escape(s.Name, e.SectionStart, e.SectionEnd), e.SectionEnd); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range s.Settings {
+		if _, err := fmt.Fprintf(e.w, "%v%c%v\n", e.escape(p.Key, e.Equals), e.Equals, e.escape(p.Value, e.Equals)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Encoder) encodeStruct(val reflect.Value) error {
+	t := val.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name, omitempty := parseTag(f)
+
+		fv := val.Field(i)
+		for fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				break
+			}
+			fv = fv.Elem()
+		}
+
+		if omitempty && fv.IsZero() {
+			continue
+		}
+
+		var settings []Pair
+		switch fv.Kind() {
+		case reflect.Struct:
+			settings = structSettings(fv)
+		case reflect.Map:
+			for _, k := range fv.MapKeys() {
+				settings = append(settings, Pair{
+					Key:   fmt.Sprint(k.Interface()),
+					Value: fmt.Sprint(fv.MapIndex(k).Interface()),
+				})
+			}
+		default:
+			return &EncodeTypeError{t}
+		}
+
+		if e.SortKeys {
+			sort.Slice(settings, func(i, j int) bool {
+				return settings[i].Key < settings[j].Key
+			})
+		}
+
+		if err := e.writeSection(Group{Name: name, Settings: settings}); err != nil {
+			return err
+		}
+	}
+
+	return e.w.Flush()
+}
+
+func structSettings(val reflect.Value) []Pair {
+	t := val.Type()
+
+	var settings []Pair
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name, omitempty := parseTag(f)
+
+		fv := val.Field(i)
+		if omitempty && fv.IsZero() {
+			continue
+		}
+
+		settings = append(settings, Pair{
+			Key:   name,
+			Value: fmt.Sprint(fv.Interface()),
+		})
+	}
+
+	return settings
+}
+
+func parseTag(f reflect.StructField) (name string, omitempty bool) {
+	name = f.Name
+
+	tag := f.Tag.Get("ini")
+	if tag == "" {
+		return name, false
+	}
+
+	parts := strings.Split(tag, ",")
+	if parts[0] != "" {
+		name = parts[0]
+	}
+
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+
+	return name, omitempty
+}
+
+func splitSectionKey(key string) (section, name string) {
+	i := strings.IndexRune(key, '/')
+	if i < 0 {
+		return "", key
+	}
+
+	return key[:i], key[i+1:]
+}
+
+// escape escapes s so that it can be read back by a Parser configured
+// with the same Comments, Escaper, Escapes, and extra runes, without
+// any of those runes, or a newline, being mistaken for the end of the
+// value or the start of a comment. It defers to escapeConfig, the
+// same escaping implementation SectionToken and SettingToken use to
+// re-escape themselves in String().
+func (e *Encoder) escape(s string, extra ...rune) string {
+	return e.escapeConfig().escape(s, extra...)
+}
+
+// EncodeTypeError is returned by (*Encoder).Encode when v is not a
+// type that can be encoded.
+type EncodeTypeError struct {
+	Type reflect.Type
+}
+
+func (err *EncodeTypeError) Error() string {
+	return "Can't encode type: " + err.Type.String()
+}
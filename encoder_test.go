@@ -0,0 +1,129 @@
+package ini_test
+
+import (
+	"fmt"
+	"github.com/DeedleFake/ini"
+	"strings"
+)
+
+func ExampleMarshal_map() {
+	m := map[string]string{
+		"server/host": "localhost",
+		"server/port": "8080",
+	}
+
+	out, err := ini.Marshal(m)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(string(out))
+	// Output: [server]
+	// host=localhost
+	// port=8080
+}
+
+func ExampleMarshal_nestedMap() {
+	m := map[string]map[string]string{
+		"server": {"host": "localhost"},
+	}
+
+	out, err := ini.Marshal(m)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(string(out))
+	// Output: [server]
+	// host=localhost
+}
+
+func ExampleEncoder_Encode_struct() {
+	type Server struct {
+		Host string `ini:"host"`
+		Port int    `ini:"port"`
+	}
+
+	cfg := struct {
+		Server Server `ini:"server"`
+	}{
+		Server: Server{Host: "localhost", Port: 8080},
+	}
+
+	out, err := ini.Marshal(cfg)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(string(out))
+	// Output: [server]
+	// host=localhost
+	// port=8080
+}
+
+func ExampleEncoder_Encode_groups() {
+	groups := []ini.Group{
+		{
+			Name: "b",
+			Settings: []ini.Pair{
+				{Key: "second", Value: "2"},
+				{Key: "first", Value: "1"},
+			},
+		},
+		{Name: "a"},
+	}
+
+	var buf strings.Builder
+	e := ini.NewEncoder(&buf)
+	if err := e.Encode(groups); err != nil {
+		panic(err)
+	}
+	fmt.Print(buf.String())
+	// Output: [b]
+	// second=2
+	// first=1
+	// [a]
+}
+
+func ExampleEncoder_sortKeys() {
+	m := map[string]string{
+		"server/port": "8080",
+		"server/host": "localhost",
+	}
+
+	var buf strings.Builder
+	e := ini.NewEncoder(&buf)
+	e.SortKeys = false
+	if err := e.Encode(m); err != nil {
+		panic(err)
+	}
+
+	// Unsorted map iteration order isn't deterministic, so just check
+	// that both settings round-trip, in whichever order they land.
+	f, err := ini.Load(strings.NewReader(buf.String()))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(f.Section("server").Key("host"))
+	fmt.Println(f.Section("server").Key("port"))
+	// Output: localhost
+	// 8080
+}
+
+func ExampleEncoder_Encode_escaping() {
+	m := map[string]string{
+		"server/note": "localhost;8080",
+	}
+
+	out, err := ini.Marshal(m)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(string(out))
+
+	f, err := ini.Load(strings.NewReader(string(out)))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(f.Section("server").Key("note"))
+	// Output: [server]
+	// note=localhost\;8080
+	// localhost;8080
+}
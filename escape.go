@@ -0,0 +1,88 @@
+package ini
+
+import (
+	"bytes"
+	"strings"
+)
+
+// escapeConfig holds the runes and escape-sequence mapping used to
+// decide which runes in a value or name need escaping when it's
+// rendered back to text. It's the one implementation shared by
+// Encoder and the SectionToken/SettingToken values a Parser produces,
+// so that a customized Parser's Comments, Escaper, and Escapes
+// round-trip through String() the same way they were read, instead of
+// each writer re-deriving its own escaping rules.
+type escapeConfig struct {
+	comments string
+	escaper  rune
+	escapes  map[rune]string
+}
+
+// defaultEscapeConfig matches NewParser's defaults. It's used by a
+// SectionToken or SettingToken that wasn't produced by a Parser, such
+// as one built directly by a File's mutators.
+var defaultEscapeConfig = escapeConfig{
+	comments: defaultComments,
+	escaper:  defaultEscaper,
+	escapes:  defaultEscapes,
+}
+
+// escapeConfig returns the escapeConfig describing how p currently
+// escapes values.
+func (p *Parser) escapeConfig() escapeConfig {
+	return escapeConfig{
+		comments: p.Comments,
+		escaper:  p.Escaper,
+		escapes:  p.Escapes,
+	}
+}
+
+// escapeConfig returns the escapeConfig describing how e currently
+// escapes values.
+func (e *Encoder) escapeConfig() escapeConfig {
+	return escapeConfig{
+		comments: e.Comments,
+		escaper:  e.Escaper,
+		escapes:  e.Escapes,
+	}
+}
+
+// escape escapes s so that it can be read back by a Parser configured
+// with c's Comments, Escaper, and Escapes, without any of those runes,
+// extra, or a newline being mistaken for the end of the value or the
+// start of a comment. A zero-value c, such as on a SectionToken or
+// SettingToken built outside of a Parser, falls back to
+// defaultEscapeConfig.
+func (c escapeConfig) escape(s string, extra ...rune) string {
+	if c.escaper == 0 {
+		c = defaultEscapeConfig
+	}
+
+	special := c.comments + string(c.escaper) + "\n" + string(extra)
+
+	var buf bytes.Buffer
+	for _, r := range s {
+		if !strings.ContainsRune(special, r) {
+			buf.WriteRune(r)
+			continue
+		}
+
+		buf.WriteRune(c.escaper)
+		buf.WriteRune(c.escapeRune(r))
+	}
+
+	return buf.String()
+}
+
+// escapeRune returns the rune that, following c.escaper, reproduces r
+// according to c.escapes, falling back to r itself if there's no
+// matching escape sequence.
+func (c escapeConfig) escapeRune(r rune) rune {
+	for seq, repl := range c.escapes {
+		if repl == string(r) {
+			return seq
+		}
+	}
+
+	return r
+}
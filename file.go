@@ -0,0 +1,373 @@
+package ini
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A File is an in-memory, ordered representation of an INI document.
+// Unlike using a Parser directly, a File can be edited and written
+// back out, preserving the comments, blank lines, key order, and
+// section order of whatever was loaded, except where explicitly
+// changed.
+type File struct {
+	sections []*Section
+}
+
+// Load reads and parses an entire INI document from r into a File.
+//
+// Settings that appear before the first section header are placed
+// into the unnamed section, accessible via f.Section("").
+func Load(r io.Reader) (*File, error) {
+	f := &File{}
+	if err := loadTokens(f, NewParser(r)); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// ParseAll is like Load, but parses with a Tolerant Parser: instead of
+// stopping at the first malformed token, it resynchronizes at the
+// next line and keeps going, collecting every error it encounters
+// into the returned ErrorList. This is meant for editor and linter
+// integrations that need every diagnostic from a single pass, rather
+// than fixing and re-running one error at a time.
+func ParseAll(r io.Reader) (*File, ErrorList) {
+	f := &File{}
+
+	p := NewParser(r)
+	p.Tolerant = true
+	loadTokens(f, p)
+
+	sort.Sort(p.Errors)
+
+	return f, p.Errors
+}
+
+// loadTokens reads tokens from p into f until EOF, returning the
+// first error p.Next() returns if p isn't Tolerant.
+func loadTokens(f *File, p *Parser) error {
+	cur := f.NewSection("")
+
+	for {
+		tok, err := p.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+
+		raw := p.Raw()
+
+		switch tok := tok.(type) {
+		case *SectionToken:
+			cur = f.NewSection(tok.Name)
+			cur.raw = firstNonEmpty(raw, tok.String())
+			cur.rawName = tok.Name
+		case *SettingToken:
+			cur.items = append(cur.items, &Key{
+				Name:  tok.Left,
+				Value: tok.Right,
+
+				raw:      firstNonEmpty(raw, tok.String()),
+				rawName:  tok.Left,
+				rawValue: tok.Right,
+			})
+		case *CommentToken:
+			cur.items = append(cur.items, comment(firstNonEmpty(raw, tok.String())))
+		case *BlankLineToken:
+			cur.items = append(cur.items, blankLine{})
+		}
+	}
+}
+
+// firstNonEmpty returns raw, or fallback if raw is "". It's used to
+// prefer a token's literal source text, when the Parser that produced
+// it captured one, over its canonicalized String() form.
+func firstNonEmpty(raw, fallback string) string {
+	if raw != "" {
+		return raw
+	}
+
+	return fallback
+}
+
+// WriteTo writes f back out in INI format. Any section or setting
+// that hasn't been changed since it was loaded is written using its
+// original text, byte-for-byte, including its original comment and
+// escape sequences. Anything added or edited is written out using the
+// default Parser conventions.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+
+	for _, s := range f.sections {
+		if s.Name != "" {
+			header := s.raw
+			if (header == "") || (s.Name != s.rawName) {
+				header = SectionToken{Name: s.Name, start: '[', end: ']'}.String()
+			}
+
+			buf.WriteString(header)
+			buf.WriteByte('\n')
+		}
+
+		for _, it := range s.items {
+			switch it := it.(type) {
+			case *Key:
+				line := it.raw
+				if (line == "") || (it.Name != it.rawName) || (it.Value != it.rawValue) {
+					line = SettingToken{Left: it.Name, Right: it.Value, equals: '='}.String()
+				}
+
+				buf.WriteString(line)
+				buf.WriteByte('\n')
+			case comment:
+				buf.WriteString(string(it))
+				buf.WriteByte('\n')
+			case blankLine:
+				buf.WriteByte('\n')
+			}
+		}
+	}
+
+	return buf.WriteTo(w)
+}
+
+// Sections returns f's sections, in the order they appear in f,
+// including the unnamed section holding any settings that appear
+// before the first section header.
+func (f *File) Sections() []*Section {
+	return append([]*Section(nil), f.sections...)
+}
+
+// Section returns the first section in f named name, or nil if there
+// isn't one. The unnamed section, containing settings that appear
+// before the first section header, is accessed with name == "".
+func (f *File) Section(name string) *Section {
+	for _, s := range f.sections {
+		if s.Name == name {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// NewSection appends a new, empty section named name to f and returns
+// it, regardless of whether a section by that name already exists.
+func (f *File) NewSection(name string) *Section {
+	s := &Section{Name: name}
+	f.sections = append(f.sections, s)
+	return s
+}
+
+// profileKeys lists the settings, in precedence order, that name a
+// profile's parent in the AWS shared-config convention.
+var profileKeys = []string{"source_profile", "include_profile"}
+
+// findProfile returns the section corresponding to the named AWS-style
+// profile: either a section named exactly name, as with the unnamed
+// "[default]" profile, or one named "profile "+name, as with
+// "[profile dev]".
+func (f *File) findProfile(name string) *Section {
+	for _, s := range f.sections {
+		if (s.Name == name) || (s.Name == "profile "+name) {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// ResolveProfile resolves the named AWS-style profile, following its
+// source_profile and include_profile chain and merging each ancestor's
+// settings into the result, with settings closer to name taking
+// precedence over ones inherited from further up the chain.
+//
+// If the chain contains a cycle, ResolveProfile returns a *CycleError
+// listing the profiles involved.
+func (f *File) ResolveProfile(name string) (*Section, error) {
+	return f.resolveProfile(name, nil)
+}
+
+func (f *File) resolveProfile(name string, path []string) (*Section, error) {
+	for _, p := range path {
+		if p == name {
+			return nil, &CycleError{Profiles: append(path, name)}
+		}
+	}
+	path = append(path, name)
+
+	s := f.findProfile(name)
+	if s == nil {
+		return nil, errors.New("ini: no such profile: " + name)
+	}
+
+	merged := &Section{Name: name}
+	for _, key := range profileKeys {
+		parentKey := s.Key(key)
+		if parentKey == nil {
+			continue
+		}
+
+		parent, err := f.resolveProfile(parentKey.Value, path)
+		if err != nil {
+			return nil, err
+		}
+
+		mergeKeys(merged, parent)
+	}
+	mergeKeys(merged, s)
+
+	return merged, nil
+}
+
+// mergeKeys copies src's settings into dst, overwriting any
+// already-present setting of the same name.
+func mergeKeys(dst, src *Section) {
+	for _, it := range src.items {
+		k, ok := it.(*Key)
+		if !ok {
+			continue
+		}
+
+		if existing := dst.Key(k.Name); existing != nil {
+			existing.Value = k.Value
+			continue
+		}
+
+		dst.items = append(dst.items, &Key{Name: k.Name, Value: k.Value})
+	}
+}
+
+// A CycleError is returned by (*File).ResolveProfile when a profile's
+// source_profile or include_profile chain refers back to itself.
+type CycleError struct {
+	// Profiles lists the profiles involved in the cycle, in the order
+	// they were visited, ending with the profile that closed the loop.
+	Profiles []string
+}
+
+func (err *CycleError) Error() string {
+	return "ini: profile cycle: " + strings.Join(err.Profiles, " -> ")
+}
+
+// A Section is an ordered collection of settings, corresponding to a
+// single INI section. It may also hold comments, interspersed in
+// their original positions relative to the settings around them.
+type Section struct {
+	// Name is the name of the section.
+	Name string
+
+	raw     string
+	rawName string
+
+	items []sectionItem
+}
+
+// sectionItem is implemented by the types that can appear, in order,
+// inside of a Section: *Key, comment, and blankLine.
+type sectionItem interface {
+	isSectionItem()
+}
+
+// comment is a verbatim comment line, kept only to be written back
+// out in its original position.
+type comment string
+
+func (comment) isSectionItem() {}
+
+// blankLine is an empty line, kept only to be written back out in its
+// original position.
+type blankLine struct{}
+
+func (blankLine) isSectionItem() {}
+
+// Keys returns s's settings, in the order they appear in s, skipping
+// any interspersed comments.
+func (s *Section) Keys() []*Key {
+	var keys []*Key
+	for _, it := range s.items {
+		if k, ok := it.(*Key); ok {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys
+}
+
+// Key returns the first setting in s named name, or nil if there
+// isn't one.
+func (s *Section) Key(name string) *Key {
+	for _, it := range s.items {
+		if k, ok := it.(*Key); ok && (k.Name == name) {
+			return k
+		}
+	}
+
+	return nil
+}
+
+// NewKey appends a new setting named name with the given value to s
+// and returns it, regardless of whether a setting by that name
+// already exists.
+func (s *Section) NewKey(name, value string) *Key {
+	k := &Key{Name: name, Value: value}
+	s.items = append(s.items, k)
+	return k
+}
+
+// DeleteKey removes the first setting in s named name, reporting
+// whether a matching setting was found.
+func (s *Section) DeleteKey(name string) bool {
+	for i, it := range s.items {
+		if k, ok := it.(*Key); ok && (k.Name == name) {
+			s.items = append(s.items[:i], s.items[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// A Key is a single setting inside of a Section.
+type Key struct {
+	// Name is the left-hand side of the setting.
+	Name string
+
+	// Value is the right-hand side of the setting, as a string.
+	Value string
+
+	raw               string
+	rawName, rawValue string
+}
+
+func (*Key) isSectionItem() {}
+
+// String returns k's value.
+func (k *Key) String() string {
+	return k.Value
+}
+
+// Int parses k's value as an int.
+func (k *Key) Int() (int, error) {
+	return strconv.Atoi(k.Value)
+}
+
+// Bool parses k's value as a bool.
+func (k *Key) Bool() (bool, error) {
+	return strconv.ParseBool(k.Value)
+}
+
+// Duration parses k's value as a time.Duration.
+func (k *Key) Duration() (time.Duration, error) {
+	return time.ParseDuration(k.Value)
+}
@@ -0,0 +1,190 @@
+package ini_test
+
+import (
+	"fmt"
+	"github.com/DeedleFake/ini"
+	"strings"
+)
+
+func ExampleLoad() {
+	const example = `[server]
+host=localhost
+# the listen port
+port=8080`
+
+	f, err := ini.Load(strings.NewReader(example))
+	if err != nil {
+		panic(err)
+	}
+
+	server := f.Section("server")
+	fmt.Println(server.Key("host"))
+
+	port, err := server.Key("port").Int()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(port)
+	// Output: localhost
+	// 8080
+}
+
+func ExampleFile_WriteTo() {
+	const example = `[server]
+host=localhost
+# the listen port
+port=8080`
+
+	f, err := ini.Load(strings.NewReader(example))
+	if err != nil {
+		panic(err)
+	}
+
+	f.Section("server").Key("host").Value = "example.com"
+
+	var buf strings.Builder
+	if _, err := f.WriteTo(&buf); err != nil {
+		panic(err)
+	}
+	fmt.Println(buf.String())
+	// Output: [server]
+	// host=example.com
+	// # the listen port
+	// port=8080
+}
+
+func ExampleFile_ResolveProfile() {
+	const example = `[default]
+region=us-east-1
+output=json
+
+[profile dev]
+source_profile=default
+region=us-west-2`
+
+	f, err := ini.Load(strings.NewReader(example))
+	if err != nil {
+		panic(err)
+	}
+
+	dev, err := f.ResolveProfile("dev")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(dev.Key("region"))
+	fmt.Println(dev.Key("output"))
+	// Output: us-west-2
+	// json
+}
+
+func ExampleParseAll() {
+	const example = `[one]
+good=1
+malformed line
+[two]
+another=2`
+
+	f, errs := ini.ParseAll(strings.NewReader(example))
+	fmt.Println(errs)
+	fmt.Println(f.Section("one").Key("good"))
+	fmt.Printf("%q\n", f.Section("one").Key("malformed line").Value)
+	fmt.Println(f.Section("two").Key("another"))
+	// Output: 3:0: Newline in left-hand side
+	// 1
+	// ""
+	// 2
+}
+
+func ExampleLoad_escapedComment() {
+	const example = `[server]
+host=localhost\;8080`
+
+	f, err := ini.Load(strings.NewReader(example))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(f.Section("server").Key("host"))
+
+	var buf strings.Builder
+	if _, err := f.WriteTo(&buf); err != nil {
+		panic(err)
+	}
+	fmt.Println(buf.String())
+	// Output: localhost;8080
+	// [server]
+	// host=localhost\;8080
+}
+
+func ExampleEncoder_Encode_file() {
+	const example = `[server]
+host=localhost
+port=8080`
+
+	f, err := ini.Load(strings.NewReader(example))
+	if err != nil {
+		panic(err)
+	}
+
+	out, err := ini.Marshal(f)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(string(out))
+	// Output: [server]
+	// host=localhost
+	// port=8080
+}
+
+func ExampleFile_WriteTo_blankLines() {
+	const example = "[a]\nfoo=bar\n\nbaz=qux\n"
+
+	f, err := ini.Load(strings.NewReader(example))
+	if err != nil {
+		panic(err)
+	}
+
+	var buf strings.Builder
+	if _, err := f.WriteTo(&buf); err != nil {
+		panic(err)
+	}
+	fmt.Print(buf.String() == example)
+	// Output: true
+}
+
+func ExampleFile_WriteTo_nonCanonicalEscape() {
+	const example = `[a]
+foo=ba\zr`
+
+	f, err := ini.Load(strings.NewReader(example))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(f.Section("a").Key("foo"))
+
+	var buf strings.Builder
+	if _, err := f.WriteTo(&buf); err != nil {
+		panic(err)
+	}
+	fmt.Print(buf.String() == example+"\n")
+	// Output: bazr
+	// true
+}
+
+func ExampleFile_ResolveProfile_cycle() {
+	const example = `[profile a]
+source_profile=b
+
+[profile b]
+source_profile=a`
+
+	f, err := ini.Load(strings.NewReader(example))
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = f.ResolveProfile("a")
+	fmt.Println(err)
+	// Output: ini: profile cycle: a -> b -> a
+}
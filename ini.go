@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"reflect"
 	"strings"
 	"unicode"
 )
@@ -57,12 +58,42 @@ type Parser struct {
 	// Default: ]
 	SectionEnd rune
 
+	// SplitSectionNames controls whether a section header containing
+	// whitespace, such as the AWS-style [profile dev], is split into a
+	// SectionToken.Name of "profile" and a SectionToken.Subname of
+	// "dev". When false, Name holds the full, unsplit header text and
+	// Subname is always empty.
+	//
+	// Default: false
+	SplitSectionNames bool
+
 	// Equals is the rune that separates the left side of a setting
 	// token from the right.
 	//
 	// Default: =
 	Equals rune
 
+	// If Tolerant is true, a malformed token does not cause Next to
+	// fail. Instead, the error is appended to Errors, the state
+	// machine resynchronizes by skipping to the next line, and Next
+	// returns a best-effort Token for the malformed input where one
+	// can be salvaged, such as a SettingToken with an empty Right if
+	// the Equals rune was missing.
+	//
+	// Default: false
+	Tolerant bool
+
+	// Errors accumulates the errors encountered while Tolerant is true.
+	Errors ErrorList
+
+	// If StrictEscapes is true, a Comments rune that appears in a
+	// value without being escaped is a ParseError instead of silently
+	// ending the value and starting a comment. This matches the
+	// stricter dialects used by tools like Git's config parser.
+	//
+	// Default: false
+	StrictEscapes bool
+
 	r    *bufio.Reader
 	line int
 	pos  int
@@ -72,32 +103,59 @@ type Parser struct {
 
 	buf bytes.Buffer
 	t   Token
+
+	// src, when hasRaw is true, holds every byte read from the
+	// underlying io.Reader, so that Raw can recover the literal source
+	// text of a token instead of the canonicalized one String()
+	// reconstructs. bytePos is p's logical read position within src,
+	// accounting for any rune that's been unread; lastSize is the byte
+	// size of the most recently read rune, used to undo that tracking
+	// on an unread and to exclude a token's terminator from its span.
+	src      bytes.Buffer
+	hasRaw   bool
+	bytePos  int
+	lastSize int
+	rawStart int
+	rawEnd   int
+
+	// pendingTerm is set by section when it finalizes a SectionToken,
+	// since, unlike a setting or comment, it doesn't consume its own
+	// line's trailing newline. It tells blankLine that the very next
+	// '\n' it sees is just that leftover terminator, not an empty line
+	// of its own.
+	pendingTerm bool
+}
+
+// Default Comments, Escaper, and Escapes for NewParser, also used by
+// SettingToken.String() and SectionToken.String() to re-escape a
+// token built or edited outside of a Parser.
+const (
+	defaultComments = "#;"
+	defaultEscaper  = '\\'
+)
+
+var defaultEscapes = map[rune]string{
+	'0':  "\000",
+	'a':  "\a",
+	'b':  "\b",
+	't':  "\t",
+	'r':  "\r",
+	'n':  "\n",
+	'\n': "",
 }
 
 // NewParser initializes a new Parser for the given io.Reader.
 func NewParser(r io.Reader) *Parser {
-	var rr *bufio.Reader
-	if br, ok := r.(*bufio.Reader); ok {
-		rr = br
-	} else {
-		rr = bufio.NewReader(r)
+	escapes := make(map[rune]string, len(defaultEscapes))
+	for seq, repl := range defaultEscapes {
+		escapes[seq] = repl
 	}
 
-	return &Parser{
-		r: rr,
-
-		Comments: "#;",
+	p := &Parser{
+		Comments: defaultComments,
 
-		Escaper: '\\',
-		Escapes: map[rune]string{
-			'0':  "\000",
-			'a':  "\a",
-			'b':  "\b",
-			't':  "\t",
-			'r':  "\r",
-			'n':  "\n",
-			'\n': "",
-		},
+		Escaper:                    defaultEscaper,
+		Escapes:                    escapes,
 		AllowUnknownEscapeSequence: true,
 
 		SectionStart: '[',
@@ -105,36 +163,54 @@ func NewParser(r io.Reader) *Parser {
 
 		Equals: '=',
 	}
+
+	if br, ok := r.(*bufio.Reader); ok {
+		// A pre-wrapped *bufio.Reader has already been reading from its
+		// source before we see it, so there's nowhere to splice in the
+		// tee that backs Raw; such a Parser just reports "" from Raw.
+		p.r = br
+	} else {
+		p.r = bufio.NewReader(io.TeeReader(r, &p.src))
+		p.hasRaw = true
+	}
+
+	return p
 }
 
 func (p *Parser) start(r rune) (stateFunc, error) {
 	p.buf.Reset()
+	p.rawStart = p.bytePos - p.lastSize
 
 	if unicode.IsSpace(r) {
+		if r == '\n' {
+			return p.blankLine()
+		}
+
 		return (*Parser).whitespace, nil
 	}
 
-	switch r {
-	case p.SectionStart:
+	if r == p.SectionStart {
 		return (*Parser).section, nil
-	case '\n':
-		return (*Parser).start, nil
 	}
 
 	if strings.ContainsRune(p.Comments, r) {
-		p.r.UnreadRune()
+		p.unread()
 
 		return (*Parser).comment, nil
 	}
 
-	p.r.UnreadRune()
+	p.unread()
 
 	return (*Parser).left, nil
 }
 
 func (p *Parser) whitespace(r rune) (stateFunc, error) {
+	if r == '\n' {
+		return p.blankLine()
+	}
+
 	if strings.ContainsRune(p.Comments, r) {
-		p.r.UnreadRune()
+		p.unread()
 
 		return (*Parser).comment, nil
 	}
@@ -143,22 +219,62 @@ func (p *Parser) whitespace(r rune) (stateFunc, error) {
 		return (*Parser).whitespace, nil
 	}
 
-	p.r.UnreadRune()
+	p.unread()
 
 	return (*Parser).start, nil
 }
 
+// blankLine finishes an empty or whitespace-only line as a
+// BlankLineToken, unless the newline that ended it is actually the one
+// Next synthesizes at EOF (no line there at all) or the one a
+// preceding SectionToken left unconsumed (the section's own line, not
+// a blank one after it).
+func (p *Parser) blankLine() (stateFunc, error) {
+	if p.pendingTerm {
+		p.pendingTerm = false
+
+		return (*Parser).start, nil
+	}
+
+	if p.err == io.EOF {
+		return nil, nil
+	}
+
+	p.t = &BlankLineToken{}
+	p.rawEnd = p.rawStart
+
+	return nil, nil
+}
+
+// unread puts the most recently read rune back, so it will be read
+// again by the next ReadRune, and reverses the corresponding advance
+// of bytePos.
+func (p *Parser) unread() {
+	p.r.UnreadRune()
+	p.bytePos -= p.lastSize
+}
+
 func (p *Parser) section(r rune) (stateFunc, error) {
 	switch r {
 	case p.SectionStart:
 		return nil, p.parseError(fmt.Sprintf("Unexpected rune: %q", r))
 	case p.SectionEnd:
-		p.t = &SectionToken{
+		name := p.buf.String()
+
+		t := &SectionToken{
 			start: p.SectionStart,
 			end:   p.SectionEnd,
 
-			Name: p.buf.String(),
+			Name: name,
+			esc:  p.escapeConfig(),
 		}
+		if p.SplitSectionNames {
+			t.Name, t.Subname = splitSectionName(name)
+		}
+
+		p.t = t
+		p.rawEnd = p.bytePos
+		p.pendingTerm = true
 
 		return nil, nil
 	case p.Escaper:
@@ -185,6 +301,8 @@ func (p *Parser) comment(r rune) (stateFunc, error) {
 
 	if r == '\n' {
 		p.t.(*CommentToken).Comment = p.buf.String()
+		p.rawEnd = p.bytePos - p.lastSize
+		p.pendingTerm = false
 
 		return nil, nil
 	}
@@ -203,6 +321,7 @@ func (p *Parser) left(r rune) (stateFunc, error) {
 			equals: r,
 
 			Left: p.buf.String(),
+			esc:  p.escapeConfig(),
 		}
 
 		p.buf.Reset()
@@ -225,6 +344,8 @@ func (p *Parser) right(r rune) (stateFunc, error) {
 	switch r {
 	case '\n':
 		p.t.(*SettingToken).Right = p.buf.String()
+		p.rawEnd = p.bytePos - p.lastSize
+		p.pendingTerm = false
 
 		return nil, nil
 	case p.Escaper:
@@ -232,9 +353,14 @@ func (p *Parser) right(r rune) (stateFunc, error) {
 	}
 
 	if strings.ContainsRune(p.Comments, r) {
-		p.r.UnreadRune()
+		if p.StrictEscapes {
+			return nil, p.parseError(fmt.Sprintf("Unescaped %q in value", r))
+		}
+
+		p.unread()
 
 		p.t.(*SettingToken).Right = p.buf.String()
+		p.rawEnd = p.bytePos
 
 		return nil, nil
 	}
@@ -247,17 +373,32 @@ func (p *Parser) right(r rune) (stateFunc, error) {
 func (p *Parser) escape(r rune) (stateFunc, error) {
 	if str, ok := p.Escapes[r]; ok {
 		p.buf.WriteString(str)
+	} else if p.isSpecial(r) {
+		// Comments, Equals, SectionStart, SectionEnd, and Escaper are
+		// always escapable, regardless of AllowUnknownEscapeSequence,
+		// since they're what let a value or section name contain a rune
+		// that would otherwise be structural.
+		p.buf.WriteRune(r)
+	} else if p.AllowUnknownEscapeSequence {
+		p.buf.WriteRune(r)
 	} else {
-		if p.AllowUnknownEscapeSequence {
-			p.buf.WriteRune(r)
-		} else {
-			return nil, p.parseError(fmt.Sprintf("Unknown escape sequence: %q", r))
-		}
+		return nil, p.parseError(fmt.Sprintf("Unknown escape sequence: %q", r))
 	}
 
 	return p.last, nil
 }
 
+// isSpecial reports whether r is one of the runes that structure an
+// INI document: the start of a comment, the setting separator, or a
+// section delimiter.
+func (p *Parser) isSpecial(r rune) bool {
+	return strings.ContainsRune(p.Comments, r) ||
+		(r == p.Equals) ||
+		(r == p.SectionStart) ||
+		(r == p.SectionEnd) ||
+		(r == p.Escaper)
+}
+
 // Next reads the next token from the underlying io.Reader. It returns
 // an io.EOF when there are no more tokens available.
 func (p *Parser) Next() (t Token, err error) {
@@ -276,16 +417,20 @@ func (p *Parser) Next() (t Token, err error) {
 	state := (*Parser).start
 
 	for {
-		r, _, err := p.r.ReadRune()
+		r, size, err := p.r.ReadRune()
 		if err != nil {
 			if err == io.EOF {
 				p.err = err
 				r = '\n'
+				size = 0
 			} else {
 				return nil, err
 			}
 		}
 
+		p.bytePos += size
+		p.lastSize = size
+
 		if r == '\n' {
 			p.line++
 			p.pos = 0
@@ -295,7 +440,30 @@ func (p *Parser) Next() (t Token, err error) {
 
 		newState, err := state(p, r)
 		if err != nil {
-			return nil, err
+			pe, ok := err.(*ParseError)
+			if !p.Tolerant || !ok {
+				return nil, err
+			}
+
+			p.Errors = append(p.Errors, pe)
+
+			tok := p.recover(state)
+
+			// A recovered token's raw span, if any, only covers what
+			// was consumed before the error; resync below consumes the
+			// rest of the line, so there's no reliable literal source
+			// text for Raw to return.
+			p.rawEnd = p.rawStart
+
+			// Whatever was pending on the previous line, the error and
+			// the resync below take us to the start of a new one.
+			p.pendingTerm = false
+
+			if r != '\n' {
+				p.resync()
+			}
+
+			return tok, nil
 		}
 
 		p.last = state
@@ -313,6 +481,92 @@ func (p *Parser) Next() (t Token, err error) {
 	return p.t, nil
 }
 
+// Raw returns the literal source text of the token most recently
+// returned by Next, before any escape decoding or canonicalization, or
+// "" if there isn't one to return, such as when p was constructed over
+// an already-wrapped *bufio.Reader, or the token came from Tolerant
+// error recovery.
+func (p *Parser) Raw() string {
+	if !p.hasRaw || (p.rawEnd <= p.rawStart) {
+		return ""
+	}
+
+	return string(p.src.Bytes()[p.rawStart:p.rawEnd])
+}
+
+// recover synthesizes a best-effort Token for the malformed input that
+// caused ctx to fail, for use by Tolerant parsing. It returns nil if
+// nothing usable was in progress.
+func (p *Parser) recover(ctx stateFunc) Token {
+	if sameStateFunc(ctx, (*Parser).escape) {
+		ctx = p.last
+	}
+
+	switch {
+	case sameStateFunc(ctx, (*Parser).section):
+		t := &SectionToken{
+			start: p.SectionStart,
+			end:   p.SectionEnd,
+
+			Name: p.buf.String(),
+			esc:  p.escapeConfig(),
+		}
+		if p.SplitSectionNames {
+			t.Name, t.Subname = splitSectionName(t.Name)
+		}
+
+		return t
+	case sameStateFunc(ctx, (*Parser).left):
+		return &SettingToken{
+			equals: p.Equals,
+
+			Left: p.buf.String(),
+			esc:  p.escapeConfig(),
+		}
+	case sameStateFunc(ctx, (*Parser).right):
+		t := p.t.(*SettingToken)
+		t.Right = p.buf.String()
+
+		return t
+	default:
+		return nil
+	}
+}
+
+// resync discards runes up to and including the next newline, so that
+// a Tolerant Parser can resume at the start of the following line
+// after a malformed token.
+func (p *Parser) resync() {
+	for {
+		r, size, err := p.r.ReadRune()
+		if err != nil {
+			p.err = io.EOF
+
+			return
+		}
+
+		p.bytePos += size
+		p.lastSize = size
+
+		if r == '\n' {
+			p.line++
+			p.pos = 0
+
+			return
+		}
+
+		p.pos++
+	}
+}
+
+// sameStateFunc reports whether a and b are the same stateFunc.
+// stateFunc values aren't comparable with ==, as they're
+// method-expression closures, so this compares their underlying code
+// pointers instead.
+func sameStateFunc(a, b stateFunc) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
+
 // ParseError is returned by (*Parser).Next() if it encounters an error.
 type ParseError struct {
 	Line int
@@ -332,29 +586,94 @@ func (err *ParseError) Error() string {
 	return fmt.Sprintf("%v:%v: %v", err.Line, err.Pos, err.Err)
 }
 
+// An ErrorList collects the errors found by a Tolerant Parser in a
+// single pass. It implements sort.Interface, ordering errors by Line
+// and then Pos.
+type ErrorList []*ParseError
+
+// Error joins the contained errors, one per line.
+func (list ErrorList) Error() string {
+	var buf bytes.Buffer
+
+	for i, err := range list {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+
+		buf.WriteString(err.Error())
+	}
+
+	return buf.String()
+}
+
+// Err returns list as an error, or nil if list is empty.
+func (list ErrorList) Err() error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	return list
+}
+
+func (list ErrorList) Len() int      { return len(list) }
+func (list ErrorList) Swap(i, j int) { list[i], list[j] = list[j], list[i] }
+
+func (list ErrorList) Less(i, j int) bool {
+	if list[i].Line != list[j].Line {
+		return list[i].Line < list[j].Line
+	}
+
+	return list[i].Pos < list[j].Pos
+}
+
 type Token interface{}
 
 // A SectionToken represents a section header. For example,
 //
 //	[Name]
 type SectionToken struct {
-	// Name is the name of the section.
+	// Name is the name of the section. If Parser.SplitSectionNames is
+	// false, this is the full header text, such as "profile dev".
 	Name string
 
+	// Subname is everything after the first run of whitespace in the
+	// header, such as "dev" in "[profile dev]". It is only populated
+	// when Parser.SplitSectionNames is true.
+	Subname string
+
 	start, end rune
+	esc        escapeConfig
 }
 
-// String recreates the original section token in the INI file.
+// String recreates the original section token in the INI file,
+// re-escaping any rune in Name or Subname that the Parser which
+// produced t would otherwise treat as structural, so that Parse ->
+// String is a fixed point, even for a customized Parser.
 func (t SectionToken) String() string {
 	var buf bytes.Buffer
 
 	buf.WriteRune(t.start)
-	buf.WriteString(t.Name)
+	buf.WriteString(t.esc.escape(t.Name, t.start, t.end))
+	if t.Subname != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(t.esc.escape(t.Subname, t.start, t.end))
+	}
 	buf.WriteRune(t.end)
 
 	return buf.String()
 }
 
+// splitSectionName splits a section header's raw text on its first
+// run of whitespace, as used by Parser.SplitSectionNames.
+func splitSectionName(name string) (string, string) {
+	i := strings.IndexFunc(name, unicode.IsSpace)
+	if i < 0 {
+		return name, ""
+	}
+
+	return name[:i], strings.TrimLeftFunc(name[i:], unicode.IsSpace)
+}
+
 // A SettingToken represents a setting. For example,
 //
 //	left=right
@@ -366,15 +685,19 @@ type SettingToken struct {
 	Right string
 
 	equals rune
+	esc    escapeConfig
 }
 
-// String recreates the original setting token.
+// String recreates the original setting token, re-escaping any rune
+// in Left or Right that the Parser which produced t would otherwise
+// treat as structural, so that Parse -> String is a fixed point, even
+// for a customized Parser.
 func (t SettingToken) String() string {
 	var buf bytes.Buffer
 
-	buf.WriteString(t.Left)
+	buf.WriteString(t.esc.escape(t.Left, t.equals))
 	buf.WriteRune(t.equals)
-	buf.WriteString(t.Right)
+	buf.WriteString(t.esc.escape(t.Right, t.equals))
 
 	return buf.String()
 }
@@ -397,3 +720,15 @@ func (t CommentToken) String() string {
 
 	return buf.String()
 }
+
+// A BlankLineToken represents a line with nothing on it but optional
+// whitespace: no section, setting, or comment. Next produces one for
+// every such line so that a File can preserve blank lines between the
+// tokens around them instead of silently dropping them.
+type BlankLineToken struct{}
+
+// String returns "", since a blank line has no content of its own;
+// it's the surrounding newline that represents it.
+func (t BlankLineToken) String() string {
+	return ""
+}
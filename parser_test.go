@@ -73,3 +73,132 @@ other=some\n\
 	//	other: some
 	//#thing
 }
+
+func TestSplitSectionNames(t *testing.T) {
+	const example = `[profile dev]
+region=us-west-2`
+
+	p := ini.NewParser(strings.NewReader(example))
+	p.SplitSectionNames = true
+
+	tok, err := p.Next()
+	if err != nil {
+		t.Fatalf("p.Next(): %v", err)
+	}
+
+	sec, ok := tok.(*ini.SectionToken)
+	if !ok {
+		t.Fatalf("expected *ini.SectionToken, got %T", tok)
+	}
+	if (sec.Name != "profile") || (sec.Subname != "dev") {
+		t.Fatalf("got Name %q, Subname %q", sec.Name, sec.Subname)
+	}
+}
+
+func TestEscapeSpecialRunes(t *testing.T) {
+	const example = `key=a\;b\#c\=d`
+
+	p := ini.NewParser(strings.NewReader(example))
+	p.AllowUnknownEscapeSequence = false
+
+	tok, err := p.Next()
+	if err != nil {
+		t.Fatalf("p.Next(): %v", err)
+	}
+
+	s, ok := tok.(*ini.SettingToken)
+	if !ok {
+		t.Fatalf("expected *ini.SettingToken, got %T", tok)
+	}
+	if s.Right != "a;b#c=d" {
+		t.Fatalf("got Right %q, want %q", s.Right, "a;b#c=d")
+	}
+	if got := s.String(); got != example {
+		t.Fatalf("(*SettingToken).String(): got %q, want %q", got, example)
+	}
+}
+
+func TestEscapeSpecialRunesCustomParser(t *testing.T) {
+	const example = `key=a\!b`
+
+	p := ini.NewParser(strings.NewReader(example))
+	p.Comments = "#;!"
+
+	tok, err := p.Next()
+	if err != nil {
+		t.Fatalf("p.Next(): %v", err)
+	}
+
+	s, ok := tok.(*ini.SettingToken)
+	if !ok {
+		t.Fatalf("expected *ini.SettingToken, got %T", tok)
+	}
+	if s.Right != "a!b" {
+		t.Fatalf("got Right %q, want %q", s.Right, "a!b")
+	}
+	if got := s.String(); got != example {
+		t.Fatalf("(*SettingToken).String(): got %q, want %q", got, example)
+	}
+}
+
+func TestStrictEscapes(t *testing.T) {
+	const example = `key=val;rest`
+
+	p := ini.NewParser(strings.NewReader(example))
+	p.StrictEscapes = true
+
+	_, err := p.Next()
+	if err == nil {
+		t.Fatalf("p.Next(): got nil error, want a ParseError")
+	}
+	if !strings.Contains(err.Error(), `Unescaped ';' in value`) {
+		t.Fatalf("p.Next(): got %v, want an error about an unescaped rune", err)
+	}
+}
+
+func TestTolerant(t *testing.T) {
+	const example = `[Test]
+good=1
+badline
+another=2`
+
+	p := ini.NewParser(strings.NewReader(example))
+	p.Tolerant = true
+
+	var settings []*ini.SettingToken
+	for {
+		tok, err := p.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			t.Fatalf("p.Next(): %v", err)
+		}
+
+		if s, ok := tok.(*ini.SettingToken); ok {
+			settings = append(settings, s)
+		}
+	}
+
+	want := []struct{ Left, Right string }{
+		{"good", "1"},
+		{"badline", ""},
+		{"another", "2"},
+	}
+	if len(settings) != len(want) {
+		t.Fatalf("got %v settings, want %v", len(settings), len(want))
+	}
+	for i, s := range settings {
+		if (s.Left != want[i].Left) || (s.Right != want[i].Right) {
+			t.Fatalf("setting %v: got %v=%v, want %v=%v", i, s.Left, s.Right, want[i].Left, want[i].Right)
+		}
+	}
+
+	if len(p.Errors) != 1 {
+		t.Fatalf("got %v errors, want 1", len(p.Errors))
+	}
+	if err := p.Errors.Err(); err == nil {
+		t.Fatalf("(ErrorList).Err(): got nil")
+	}
+}
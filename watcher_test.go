@@ -0,0 +1,47 @@
+package ini_test
+
+import (
+	"github.com/DeedleFake/ini"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.ini")
+	if err := os.WriteFile(path, []byte("[a]\nkey=1"), 0644); err != nil {
+		t.Fatalf("os.WriteFile(): %v", err)
+	}
+
+	w, err := ini.Watch(path, ini.WithInterval(10*time.Millisecond), ini.WithDebounce(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("ini.Watch(): %v", err)
+	}
+	defer w.Close()
+
+	initial := <-w.C()
+	if initial.Err != nil {
+		t.Fatalf("initial event: %v", initial.Err)
+	}
+	if v := initial.File.Section("a").Key("key").String(); v != "1" {
+		t.Fatalf("initial key: got %q, want %q", v, "1")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("[a]\nkey=2"), 0644); err != nil {
+		t.Fatalf("os.WriteFile(): %v", err)
+	}
+
+	select {
+	case ev := <-w.C():
+		if ev.Err != nil {
+			t.Fatalf("reload event: %v", ev.Err)
+		}
+		if v := ev.File.Section("a").Key("key").String(); v != "2" {
+			t.Fatalf("reloaded key: got %q, want %q", v, "2")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for reload event")
+	}
+}